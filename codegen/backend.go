@@ -0,0 +1,102 @@
+// Package codegen separates walking a parsed program from deciding what
+// target it is turned into: Generate does the walking, a Backend decides
+// what each node becomes.
+package codegen
+
+import (
+	"../parser"
+)
+
+
+// Backend receives one callback per node as Generate walks a program,
+// and is responsible for emitting whatever its target needs for it.
+type Backend interface{
+	EmitPreamble(useIO, useFmt bool)
+	EmitPostamble()
+
+	EmitInc(n int)
+	EmitDec(n int)
+	EmitShiftL(n int)
+	EmitShiftR(n int)
+	EmitInput()
+	EmitOutput()
+
+	EmitSet(value byte)
+	EmitMulAdd(targets []parser.MulAddTarget)
+
+	EmitLoopBegin()
+	EmitLoopEnd()
+
+	EmitFuncDef(body func())
+	EmitFuncCall()
+
+	// String returns everything emitted so far.
+	String() string
+}
+
+
+// Generate walks p and drives b through it, returning b's final output.
+func Generate(p *parser.ParseList, b Backend) string {
+	useIO, useFmt := false, false
+
+	if len(p.Nodes) > 0 {
+		if pre, ok := p.Nodes[0].(*parser.PreambleNode); ok {
+			useIO, useFmt = pre.UseIO, pre.UseFmt
+		}
+	}
+
+	b.EmitPreamble(useIO, useFmt)
+	emitNodes(b, p.Nodes)
+	b.EmitPostamble()
+
+	return b.String()
+}
+
+func emitNodes(b Backend, nodes []parser.Node) {
+	for _, node := range nodes {
+		emitNode(b, node)
+	}
+}
+
+func emitNode(b Backend, node parser.Node) {
+	switch n := node.(type) {
+		case *parser.PreambleNode, *parser.PostambleNode:
+			// Handled by Generate itself via EmitPreamble/EmitPostamble.
+
+		case *parser.IncNode:
+			b.EmitInc(n.Count())
+
+		case *parser.DecNode:
+			b.EmitDec(n.Count())
+
+		case *parser.PrevNode:
+			b.EmitShiftL(n.Count())
+
+		case *parser.NextNode:
+			b.EmitShiftR(n.Count())
+
+		case *parser.OutputNode:
+			b.EmitOutput()
+
+		case *parser.InputNode:
+			b.EmitInput()
+
+		case *parser.SetNode:
+			b.EmitSet(n.Value)
+
+		case *parser.MulAddNode:
+			b.EmitMulAdd(n.Targets)
+
+		case *parser.LoopNode:
+			b.EmitLoopBegin()
+			emitNodes(b, n.Nodes)
+			b.EmitLoopEnd()
+
+		case *parser.FunctionNode:
+			nodes := n.Nodes
+			b.EmitFuncDef(func() { emitNodes(b, nodes) })
+
+		case *parser.FuncExecNode:
+			b.EmitFuncCall()
+	}
+}
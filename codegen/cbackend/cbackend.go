@@ -0,0 +1,118 @@
+// Package cbackend is a codegen.Backend that emits portable C.
+package cbackend
+
+import (
+	"fmt"
+
+	"../../parser"
+)
+
+
+const REGISTERS = 100
+
+
+// Backend collects top-level function declarations (one per "{...}"
+// body) separately from the body of main, so generated functions can be
+// plain, named C functions registered in a function-pointer table
+// instead of requiring non-standard nested functions.
+type Backend struct{
+	decls		string
+	body		string
+	funcCount	int
+}
+
+func New() *Backend {
+	return &Backend{}
+}
+
+func (b *Backend) EmitPreamble(useIO, useFmt bool) {
+	b.decls = fmt.Sprintf(`#include <stdio.h>
+
+#define REGISTERS %d
+
+static unsigned char tape[REGISTERS];
+static int dp = 0;
+static void (*funcs[REGISTERS])(void);
+
+`, REGISTERS)
+
+	b.body = "int main(void) {\n"
+}
+
+func (b *Backend) EmitPostamble() {
+	b.body += "\treturn 0;\n}\n"
+}
+
+func (b *Backend) EmitInc(n int) {
+	b.body += fmt.Sprintf("\ttape[dp] += %d;\n", n)
+}
+
+func (b *Backend) EmitDec(n int) {
+	b.body += fmt.Sprintf("\ttape[dp] -= %d;\n", n)
+}
+
+func (b *Backend) EmitShiftL(n int) {
+	b.body += fmt.Sprintf("\tdp = (dp - %d + REGISTERS) %% REGISTERS;\n", n)
+}
+
+func (b *Backend) EmitShiftR(n int) {
+	b.body += fmt.Sprintf("\tdp = (dp + %d) %% REGISTERS;\n", n)
+}
+
+func (b *Backend) EmitOutput() {
+	b.body += "\tputchar(tape[dp]);\n"
+}
+
+func (b *Backend) EmitInput() {
+	b.body += `	{
+		int c = getchar();
+		tape[dp] = (c == EOF) ? 0 : (unsigned char)c;
+	}
+`
+}
+
+func (b *Backend) EmitSet(value byte) {
+	b.body += fmt.Sprintf("\ttape[dp] = %d;\n", value)
+}
+
+func (b *Backend) EmitMulAdd(targets []parser.MulAddTarget) {
+	for _, target := range targets {
+		b.body += fmt.Sprintf("\ttape[(dp + %d + REGISTERS) %% REGISTERS] += tape[dp] * (unsigned char)%d;\n", target.Offset, byte(target.Factor))
+	}
+
+	b.body += "\ttape[dp] = 0;\n"
+}
+
+func (b *Backend) EmitLoopBegin() {
+	b.body += "\twhile (tape[dp] != 0) {\n"
+}
+
+func (b *Backend) EmitLoopEnd() {
+	b.body += "\t}\n"
+}
+
+// EmitFuncDef compiles body into its own top-level C function and emits
+// a store of that function's pointer into the table slot for dp.
+func (b *Backend) EmitFuncDef(body func()) {
+	name := fmt.Sprintf("func_%d", b.funcCount)
+	b.funcCount++
+
+	savedBody := b.body
+	b.body = ""
+
+	body()
+
+	fnBody := b.body
+	b.body = savedBody
+
+	b.decls += fmt.Sprintf("static void %s(void) {\n%s}\n\n", name, fnBody)
+	b.body += fmt.Sprintf("\tfuncs[dp] = %s;\n", name)
+}
+
+func (b *Backend) EmitFuncCall() {
+	b.body += "\tif (funcs[dp] != NULL) { funcs[dp](); }\n"
+}
+
+func (b *Backend) String() string {
+	return b.decls + "\n" + b.body
+}
@@ -0,0 +1,95 @@
+package codegen
+
+import (
+	"strings"
+	"testing"
+
+	"../parser"
+	"./cbackend"
+	"./gobackend"
+	"./wasm"
+)
+
+
+func TestGenerateGoBackend(t *testing.T) {
+	p, err := parser.Parse("+++.")
+
+	if err != nil {
+		t.Fatalf("Parse failed: %s\n", err)
+	}
+
+	out := Generate(p, gobackend.New())
+
+	if !strings.Contains(out, "package main") {
+		t.Fatalf("Expected generated Go source to contain a package clause, got:\n%s", out)
+	}
+
+	if !strings.Contains(out, "registers[currentIndex] += 3") {
+		t.Fatalf("Expected generated Go source to increment by 3, got:\n%s", out)
+	}
+}
+
+func TestGenerateCBackend(t *testing.T) {
+	p, err := parser.Parse("+++.")
+
+	if err != nil {
+		t.Fatalf("Parse failed: %s\n", err)
+	}
+
+	out := Generate(p, cbackend.New())
+
+	if !strings.Contains(out, "int main(void)") {
+		t.Fatalf("Expected generated C source to contain a main function, got:\n%s", out)
+	}
+
+	if !strings.Contains(out, "tape[dp] += 3;") {
+		t.Fatalf("Expected generated C source to increment by 3, got:\n%s", out)
+	}
+}
+
+func TestGenerateWasmBackend(t *testing.T) {
+	p, err := parser.Parse("+++.")
+
+	if err != nil {
+		t.Fatalf("Parse failed: %s\n", err)
+	}
+
+	out := Generate(p, wasm.New())
+
+	if !strings.Contains(out, "(module") {
+		t.Fatalf("Expected generated wasm source to contain a module, got:\n%s", out)
+	}
+
+	if !strings.Contains(out, "i32.const 3") {
+		t.Fatalf("Expected generated wasm source to increment by 3, got:\n%s", out)
+	}
+}
+
+// TestGenerateNestedFuncDefsWasm checks that the wasm backend's (elem
+// ...) segment is ordered by table index, not by body-completion order:
+// for a nested "{...}" the inner body finishes (and used to get
+// appended) before the outer one, even though the outer got the lower
+// table index.
+func TestGenerateNestedFuncDefsWasm(t *testing.T) {
+	p, err := parser.Parse(">{+{-}<}")
+
+	if err != nil {
+		t.Fatalf("Parse failed: %s\n", err)
+	}
+
+	out := Generate(p, wasm.New())
+
+	if !strings.Contains(out, "(elem (i32.const 0) $func_0 $func_1)") {
+		t.Fatalf("Expected elem segment ordered by table index $func_0 $func_1, got:\n%s", out)
+	}
+}
+
+func TestGenerateNestedFuncDefsCBackend(t *testing.T) {
+	p, err := parser.Parse(">{+{-}<}")
+
+	if err != nil {
+		t.Fatalf("Parse failed: %s\n", err)
+	}
+
+	Generate(p, cbackend.New())
+}
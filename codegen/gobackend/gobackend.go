@@ -0,0 +1,165 @@
+// Package gobackend is a codegen.Backend that emits the same Go source
+// parser.Encode used to produce, so existing generated programs keep
+// working unchanged.
+package gobackend
+
+import (
+	"fmt"
+
+	"../../parser"
+)
+
+
+const REGISTERS = 100
+
+
+type Backend struct{
+	code	string
+}
+
+func New() *Backend {
+	return &Backend{}
+}
+
+func (b *Backend) EmitPreamble(useIO, useFmt bool) {
+	start := `
+package main
+`
+
+	start += "\nimport \"os\"\n"
+
+	if useIO {
+		start += "import \"io\"\n"
+	}
+
+	if useFmt {
+		start += "import \"fmt\"\n"
+	}
+
+	body := fmt.Sprintf(`
+
+const REGISTERS = %d
+
+func main() {
+	registers := make([]byte, REGISTERS)
+	functions := make([]func(), REGISTERS)
+	currentIndex := 0
+
+	// Suppress unused warnings
+	registers[0] = 0
+	functions[0] = nil
+
+	// Program begin
+`, REGISTERS)
+
+	b.code += start + body
+}
+
+func (b *Backend) EmitPostamble() {
+	b.code += `
+	// Program end
+	// Flush stdout
+	os.Stdout.Sync()
+}
+`
+}
+
+func (b *Backend) EmitInc(n int) {
+	b.code += fmt.Sprintf("\tregisters[currentIndex] += %d\n", n)
+}
+
+func (b *Backend) EmitDec(n int) {
+	b.code += fmt.Sprintf("\tregisters[currentIndex] -= %d\n", n)
+}
+
+func (b *Backend) EmitShiftL(n int) {
+	b.code += fmt.Sprintf(`
+	if currentIndex == 0 {
+		currentIndex = REGISTERS-1
+	} else {
+		currentIndex -= %d
+	}
+`, n)
+}
+
+func (b *Backend) EmitShiftR(n int) {
+	b.code += fmt.Sprintf("\tcurrentIndex = (currentIndex + %d) %% REGISTERS\n", n)
+}
+
+func (b *Backend) EmitOutput() {
+	b.code += "\tfmt.Print(string(registers[currentIndex]))\n"
+}
+
+func (b *Backend) EmitInput() {
+	b.code += `
+	{
+		_, err := fmt.Scanf("%c", &registers[currentIndex])
+
+		if err != nil {
+			if err == io.EOF {
+				registers[currentIndex] = 0
+			} else {
+				fmt.Println("Keyscan Error:", err)
+				return
+			}
+		}
+	}
+`
+}
+
+func (b *Backend) EmitSet(value byte) {
+	b.code += fmt.Sprintf("\tregisters[currentIndex] = %d\n", value)
+}
+
+func (b *Backend) EmitMulAdd(targets []parser.MulAddTarget) {
+	for _, target := range targets {
+		b.code += fmt.Sprintf(`
+	{
+		targetIndex := (currentIndex + %d) %% REGISTERS
+		if targetIndex < 0 {
+			targetIndex += REGISTERS
+		}
+		registers[targetIndex] += registers[currentIndex] * %d
+	}
+`, target.Offset, byte(target.Factor))
+	}
+
+	b.code += "\tregisters[currentIndex] = 0\n"
+}
+
+func (b *Backend) EmitLoopBegin() {
+	b.code += `	{
+		for ; registers[currentIndex] > 0; {
+`
+}
+
+func (b *Backend) EmitLoopEnd() {
+	b.code += `
+		}
+	}
+`
+}
+
+func (b *Backend) EmitFuncDef(body func()) {
+	b.code += `
+	functions[currentIndex] = func() {
+`
+
+	body()
+
+	b.code += `
+	}
+`
+}
+
+func (b *Backend) EmitFuncCall() {
+	b.code += `
+	if functions[currentIndex] != nil {
+		functions[currentIndex]()
+	}
+`
+}
+
+func (b *Backend) String() string {
+	return b.code
+}
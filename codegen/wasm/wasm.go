@@ -0,0 +1,179 @@
+// Package wasm is a codegen.Backend that emits a WebAssembly text format
+// module. The tape lives in linear memory; "{...}" function bodies are
+// compiled to real wasm functions and dispatched through a table with
+// call_indirect, since wasm has no first-class function values.
+package wasm
+
+import (
+	"fmt"
+
+	"../../parser"
+)
+
+
+const REGISTERS = 100
+
+// funcsBase is where the (index+1)-into-table slots for "{...}" bodies
+// start, right after the REGISTERS tape bytes; each slot is 4 bytes.
+const funcsBase = REGISTERS
+
+
+type Backend struct{
+	body		string
+	funcs		string
+	elems		[]string
+	funcCount	int
+
+	loopStack	[]int
+	labelCount	int
+}
+
+func New() *Backend {
+	return &Backend{}
+}
+
+// wrap folds a (possibly large or negative) shift/offset amount down to
+// a single REGISTERS-sized step at emit time, the way the Go/C backends
+// do with a runtime modulo.
+func wrap(n int) int {
+	m := n % REGISTERS
+
+	if m < 0 {
+		m += REGISTERS
+	}
+
+	return m
+}
+
+func (b *Backend) EmitPreamble(useIO, useFmt bool) {
+	b.body = ""
+	b.funcs = ""
+	b.elems = nil
+}
+
+func (b *Backend) EmitPostamble() {
+	// Nothing extra - String() assembles the full module.
+}
+
+func (b *Backend) EmitInc(n int) {
+	b.body += fmt.Sprintf("(i32.store8 (global.get $dp) (i32.add (i32.load8_u (global.get $dp)) (i32.const %d)))\n", n)
+}
+
+func (b *Backend) EmitDec(n int) {
+	b.body += fmt.Sprintf("(i32.store8 (global.get $dp) (i32.sub (i32.load8_u (global.get $dp)) (i32.const %d)))\n", n)
+}
+
+func (b *Backend) EmitShiftL(n int) {
+	b.body += fmt.Sprintf("(global.set $dp (i32.rem_u (i32.add (global.get $dp) (i32.const %d)) (i32.const %d)))\n", wrap(-n), REGISTERS)
+}
+
+func (b *Backend) EmitShiftR(n int) {
+	b.body += fmt.Sprintf("(global.set $dp (i32.rem_u (i32.add (global.get $dp) (i32.const %d)) (i32.const %d)))\n", wrap(n), REGISTERS)
+}
+
+func (b *Backend) EmitOutput() {
+	b.body += "(call $write (i32.load8_u (global.get $dp)))\n"
+}
+
+func (b *Backend) EmitInput() {
+	b.body += `(local.set $tmp (call $read))
+(i32.store8 (global.get $dp) (if (result i32) (i32.eq (local.get $tmp) (i32.const -1)) (then (i32.const 0)) (else (local.get $tmp))))
+`
+}
+
+func (b *Backend) EmitSet(value byte) {
+	b.body += fmt.Sprintf("(i32.store8 (global.get $dp) (i32.const %d))\n", value)
+}
+
+func (b *Backend) EmitMulAdd(targets []parser.MulAddTarget) {
+	for _, target := range targets {
+		off := wrap(target.Offset)
+
+		b.body += fmt.Sprintf(`(i32.store8
+	(i32.rem_u (i32.add (global.get $dp) (i32.const %d)) (i32.const %d))
+	(i32.add
+		(i32.load8_u (i32.rem_u (i32.add (global.get $dp) (i32.const %d)) (i32.const %d)))
+		(i32.mul (i32.load8_u (global.get $dp)) (i32.const %d))))
+`, off, REGISTERS, off, REGISTERS, byte(target.Factor))
+	}
+
+	b.body += "(i32.store8 (global.get $dp) (i32.const 0))\n"
+}
+
+func (b *Backend) EmitLoopBegin() {
+	lbl := b.labelCount
+	b.labelCount++
+	b.loopStack = append(b.loopStack, lbl)
+
+	b.body += fmt.Sprintf("(block $exit%d (loop $loop%d\n(br_if $exit%d (i32.eqz (i32.load8_u (global.get $dp))))\n", lbl, lbl, lbl)
+}
+
+func (b *Backend) EmitLoopEnd() {
+	lbl := b.loopStack[len(b.loopStack)-1]
+	b.loopStack = b.loopStack[:len(b.loopStack)-1]
+
+	b.body += fmt.Sprintf("(br $loop%d)))\n", lbl)
+}
+
+// funcSlotAddr computes the address of the function-table-index slot
+// for the current cell.
+func funcSlotAddr() string {
+	return fmt.Sprintf("(i32.add (i32.const %d) (i32.mul (global.get $dp) (i32.const 4)))", funcsBase)
+}
+
+func (b *Backend) EmitFuncDef(body func()) {
+	idx := b.funcCount
+	b.funcCount++
+	name := fmt.Sprintf("$func_%d", idx)
+
+	savedBody, savedStack := b.body, b.loopStack
+	b.body = ""
+	b.loopStack = nil
+
+	body()
+
+	fnBody := b.body
+	b.body, b.loopStack = savedBody, savedStack
+
+	b.funcs += fmt.Sprintf("(func %s (local $tmp i32)\n%s)\n", name, fnBody)
+
+	if idx >= len(b.elems) {
+		grown := make([]string, idx+1)
+		copy(grown, b.elems)
+		b.elems = grown
+	}
+	b.elems[idx] = name
+
+	b.body += fmt.Sprintf("(i32.store %s (i32.const %d))\n", funcSlotAddr(), idx+1)
+}
+
+func (b *Backend) EmitFuncCall() {
+	slot := funcSlotAddr()
+
+	b.body += fmt.Sprintf(`(if (i32.ne (i32.load %s) (i32.const 0))
+	(then (call_indirect (type $void) (i32.sub (i32.load %s) (i32.const 1)))))
+`, slot, slot)
+}
+
+func (b *Backend) String() string {
+	elems := ""
+	for _, name := range b.elems {
+		elems += " " + name
+	}
+
+	return fmt.Sprintf(`(module
+	(import "env" "read" (func $read (result i32)))
+	(import "env" "write" (func $write (param i32)))
+	(memory (export "memory") 1)
+	(type $void (func))
+	(table %d funcref)
+	(elem (i32.const 0)%s)
+	(global $dp (mut i32) (i32.const 0))
+
+%s
+	(func $run (export "run") (local $tmp i32)
+%s
+	)
+)
+`, b.funcCount, elems, b.funcs, b.body)
+}
@@ -0,0 +1,144 @@
+// Package interp executes a parsed program directly against an in-memory
+// tape, without going through parser.Encode and a Go compiler in between.
+package interp
+
+import (
+	"../parser"
+	"fmt"
+	"io"
+)
+
+
+const REGISTERS = 100
+
+
+// Interpreter holds all the state a running program needs: the tape,
+// the per-cell function table and the streams used for "," and ".".
+type Interpreter struct{
+	registers		[]byte
+	functions		[]func()
+	currentIndex	int
+
+	In		io.Reader
+	Out		io.Writer
+}
+
+func NewInterpreter(in io.Reader, out io.Writer) *Interpreter {
+	return &Interpreter{
+		registers:		make([]byte, REGISTERS),
+		functions:		make([]func(), REGISTERS),
+		currentIndex:	0,
+		In:				in,
+		Out:			out,
+	}
+}
+
+func (i *Interpreter) execAll(nodes []parser.Node) {
+	for _, node := range nodes {
+		i.exec(node)
+	}
+}
+
+// exec dispatches a single node to its execution behaviour. It mirrors
+// the per-node Code methods parser.Encode calls, but interprets the node
+// instead of emitting Go source for it.
+func (i *Interpreter) exec(node parser.Node) {
+	switch n := node.(type) {
+		case *parser.PreambleNode, *parser.PostambleNode:
+			// Nothing to do, these only matter for code generation.
+
+		case *parser.SetNode:
+			i.registers[i.currentIndex] = n.Value
+
+		case *parser.MulAddNode:
+			for _, target := range n.Targets {
+				idx := (i.currentIndex + target.Offset) % REGISTERS
+
+				if idx < 0 {
+					idx += REGISTERS
+				}
+
+				i.registers[idx] += i.registers[i.currentIndex] * byte(target.Factor)
+			}
+
+			i.registers[i.currentIndex] = 0
+
+		case *parser.IncNode:
+			i.registers[i.currentIndex] += byte(n.Count())
+
+		case *parser.DecNode:
+			i.registers[i.currentIndex] -= byte(n.Count())
+
+		case *parser.PrevNode:
+			for c := 0; c < n.Count(); c++ {
+				if i.currentIndex == 0 {
+					i.currentIndex = REGISTERS - 1
+				} else {
+					i.currentIndex--
+				}
+			}
+
+		case *parser.NextNode:
+			i.currentIndex = (i.currentIndex + n.Count()) % REGISTERS
+
+		case *parser.OutputNode:
+			fmt.Fprint(i.Out, string(i.registers[i.currentIndex]))
+
+		case *parser.InputNode:
+			var b [1]byte
+
+			_, err := i.In.Read(b[:])
+
+			if err != nil {
+				if err == io.EOF {
+					i.registers[i.currentIndex] = 0
+				} else {
+					fmt.Fprintln(i.Out, "Keyscan Error:", err)
+				}
+			} else {
+				i.registers[i.currentIndex] = b[0]
+			}
+
+		case *parser.LoopNode:
+			for i.registers[i.currentIndex] > 0 {
+				i.execAll(n.Nodes)
+			}
+
+		case *parser.FunctionNode:
+			nodes := n.Nodes
+			idx := i.currentIndex
+
+			i.functions[idx] = func() {
+				i.execAll(nodes)
+			}
+
+		case *parser.FuncExecNode:
+			if fn := i.functions[i.currentIndex]; fn != nil {
+				fn()
+			}
+	}
+}
+
+
+// Run walks p and executes it directly, reading "," input from in and
+// writing "." output to out.
+func Run(p *parser.ParseList, in io.Reader, out io.Writer) error {
+	i := NewInterpreter(in, out)
+
+	i.execAll(p.Nodes)
+
+	return nil
+}
+
+
+// RunString parses src and runs it, as a convenience for embedding the
+// language without shelling out to "go run" on generated code.
+func RunString(src string, in io.Reader, out io.Writer) error {
+	p, err := parser.Parse(src)
+
+	if err != nil {
+		return err
+	}
+
+	return Run(p, in, out)
+}
@@ -0,0 +1,23 @@
+package interp
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+
+func TestRunStringOutput(t *testing.T) {
+	out := &bytes.Buffer{}
+
+	// Increment the first cell to 'A' (65) and print it.
+	err := RunString(strings.Repeat("+", 65)+".", nil, out)
+
+	if err != nil {
+		t.Fatalf("RunString failed: %s\n", err)
+	}
+
+	if out.String() != "A" {
+		t.Fatalf("Expected output %q, got %q\n", "A", out.String())
+	}
+}
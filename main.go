@@ -1,27 +1,37 @@
 package main
 
 import (
+	"./codegen"
+	"./codegen/cbackend"
+	"./codegen/gobackend"
+	"./codegen/wasm"
+	"./opt"
 	"./parser"
+	"flag"
 	"fmt"
 	"os"
 	"io/ioutil"
 )
 
-func max(a,b int) int {
-	if a > b {
-		return a
+var optimize = flag.Bool("O", false, "run the optimizer pipeline before code generation")
+var target = flag.String("target", "go", "code generation target: go, c or wasm")
+
+func backendFor(target string) (codegen.Backend, error) {
+	switch target {
+		case "go":
+			return gobackend.New(), nil
+		case "c":
+			return cbackend.New(), nil
+		case "wasm":
+			return wasm.New(), nil
 	}
-	return b
-}
 
-func min(a,b int) int {
-	if a < b {
-		return a
-	}
-	return b
+	return nil, fmt.Errorf("unknown target %q (want go, c or wasm)", target)
 }
 
 func main() {
+	flag.Parse()
+
 	if false {
 	//fmt.Printf( "%#v\n", parser.Tokenize("+++") )
 
@@ -49,18 +59,24 @@ func main() {
 	p, err := parser.Parse(string(data))
 
 	if err != nil {
+		// err already renders file:line:col, a source snippet and a
+		// caret per problem found (parser.ErrorList/parser.ParseError).
 		fmt.Println(err)
+		os.Exit(1)
+	}
 
-		if pe, ok := err.(*parser.ParseError); ok {
-			s, e := pe.FaultyNode.Pos(), pe.FaultyNode.End()
-			t := 10
-			fmt.Printf( "Details: %s\n", string( data[max(s-t,0) : min(e+t, len(data))] ) )
-		}
+	if *optimize {
+		p = opt.Optimize(p)
+	}
+
+	b, err := backendFor(*target)
 
+	if err != nil {
+		fmt.Println(err)
 		os.Exit(1)
 	}
 
-	fmt.Println( parser.Encode(p) )
+	fmt.Println( codegen.Generate(p, b) )
 
 	}
 }
@@ -0,0 +1,46 @@
+package opt
+
+import (
+	"../parser"
+)
+
+
+// ClearLoop rewrites "[-]" and "[+]" into a SetNode{Value: 0}.
+type ClearLoop struct{}
+
+func (ClearLoop) Name() string {
+	return "clearloop"
+}
+
+func (c ClearLoop) Run(p *parser.ParseList) *parser.ParseList {
+	return &parser.ParseList{Nodes: transformTree(p.Nodes, clearLoops)}
+}
+
+func clearLoops(nodes []parser.Node) []parser.Node {
+	rewritten := make([]parser.Node, len(nodes))
+
+	for i, node := range nodes {
+		if loop, ok := node.(*parser.LoopNode); ok && isClearLoop(loop.Nodes) {
+			rewritten[i] = &parser.SetNode{BaseNode: loop.BaseNode, Value: 0}
+		} else {
+			rewritten[i] = node
+		}
+	}
+
+	return rewritten
+}
+
+func isClearLoop(nodes []parser.Node) bool {
+	if len(nodes) != 1 {
+		return false
+	}
+
+	switch n := nodes[0].(type) {
+		case *parser.DecNode:
+			return n.Count() == 1
+		case *parser.IncNode:
+			return n.Count() == 1
+	}
+
+	return false
+}
@@ -0,0 +1,45 @@
+package opt
+
+import (
+	"reflect"
+
+	"../parser"
+)
+
+
+// Contract merges adjacent same-typed Summarizable nodes that survived
+// earlier rewrites, the same way TokenList.Append merges them while
+// tokenizing.
+type Contract struct{}
+
+func (Contract) Name() string {
+	return "contract"
+}
+
+func (c Contract) Run(p *parser.ParseList) *parser.ParseList {
+	return &parser.ParseList{Nodes: transformTree(p.Nodes, mergeAdjacent)}
+}
+
+func mergeAdjacent(nodes []parser.Node) []parser.Node {
+	result := make([]parser.Node, 0, len(nodes))
+
+	for _, node := range nodes {
+		if len(result) > 0 {
+			last := result[len(result)-1]
+
+			if lastCountable, ok := last.(parser.Summarizable); ok {
+				if nodeCountable, ok := node.(parser.Summarizable); ok && reflect.TypeOf(last) == reflect.TypeOf(node) {
+					for i := 0; i < nodeCountable.Count(); i++ {
+						lastCountable.Add()
+					}
+
+					continue
+				}
+			}
+		}
+
+		result = append(result, node)
+	}
+
+	return result
+}
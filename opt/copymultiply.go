@@ -0,0 +1,103 @@
+package opt
+
+import (
+	"../parser"
+)
+
+
+// CopyMultiplyLoop recognizes loops that decrement the current cell by
+// one per iteration and only otherwise add constant amounts to other
+// cells, with the data pointer back where it started at the end of the
+// loop body - e.g. "[->+++<]" or "[->>+<<]" - and rewrites them to a
+// single MulAddNode.
+type CopyMultiplyLoop struct{}
+
+func (CopyMultiplyLoop) Name() string {
+	return "copymultiplyloop"
+}
+
+func (c CopyMultiplyLoop) Run(p *parser.ParseList) *parser.ParseList {
+	return &parser.ParseList{Nodes: transformTree(p.Nodes, fuseMulAddLoops)}
+}
+
+func fuseMulAddLoops(nodes []parser.Node) []parser.Node {
+	rewritten := make([]parser.Node, len(nodes))
+
+	for i, node := range nodes {
+		if loop, ok := node.(*parser.LoopNode); ok {
+			if targets, ok := asMulAddTargets(loop.Nodes); ok {
+				rewritten[i] = &parser.MulAddNode{BaseNode: loop.BaseNode, Targets: targets}
+				continue
+			}
+		}
+
+		rewritten[i] = node
+	}
+
+	return rewritten
+}
+
+// asMulAddTargets reports whether nodes is entirely made up of a single
+// leading decrement of the current cell plus a balanced sequence of
+// moves and constant +/- writes to other cells. On success it returns
+// the offset/factor pairs the equivalent MulAddNode should carry.
+func asMulAddTargets(nodes []parser.Node) ([]parser.MulAddTarget, bool) {
+	if len(nodes) == 0 {
+		return nil, false
+	}
+
+	dec, ok := nodes[0].(*parser.DecNode)
+
+	if !ok || dec.Count() != 1 {
+		return nil, false
+	}
+
+	offset := 0
+	deltas := map[int]int{}
+	order := []int{}
+
+	for _, node := range nodes[1:] {
+		switch n := node.(type) {
+			case *parser.NextNode:
+				offset += n.Count()
+			case *parser.PrevNode:
+				offset -= n.Count()
+			case *parser.IncNode:
+				if _, seen := deltas[offset]; !seen {
+					order = append(order, offset)
+				}
+				deltas[offset] += n.Count()
+			case *parser.DecNode:
+				if _, seen := deltas[offset]; !seen {
+					order = append(order, offset)
+				}
+				deltas[offset] -= n.Count()
+			default:
+				return nil, false
+		}
+	}
+
+	if offset != 0 {
+		return nil, false
+	}
+
+	targets := make([]parser.MulAddTarget, 0, len(order))
+
+	for _, off := range order {
+		if off == 0 {
+			// The loop's own cell is implicitly zeroed, it must not
+			// be among the cells being written to otherwise.
+			return nil, false
+		}
+
+		if deltas[off] != 0 {
+			targets = append(targets, parser.MulAddTarget{Offset: off, Factor: deltas[off]})
+		}
+	}
+
+	if len(targets) == 0 {
+		return nil, false
+	}
+
+	return targets, true
+}
@@ -0,0 +1,113 @@
+package opt
+
+import (
+	"../parser"
+)
+
+
+// DeadCodePass drops "+"/"-" runs that sum to zero and "<"/">" runs
+// that cancel out, since those have no observable effect.
+type DeadCodePass struct{}
+
+func (DeadCodePass) Name() string {
+	return "deadcode"
+}
+
+func (d DeadCodePass) Run(p *parser.ParseList) *parser.ParseList {
+	return &parser.ParseList{Nodes: transformTree(p.Nodes, dropDeadRuns)}
+}
+
+func dropDeadRuns(nodes []parser.Node) []parser.Node {
+	result := make([]parser.Node, 0, len(nodes))
+
+	i := 0
+	for i < len(nodes) {
+		switch {
+			case isIncDec(nodes[i]):
+				j := scanRun(nodes, i, isIncDec)
+
+				if j-i > 1 && incDecSum(nodes[i:j]) == 0 {
+					i = j
+					continue
+				}
+
+				result = append(result, nodes[i:j]...)
+				i = j
+
+			case isShift(nodes[i]):
+				j := scanRun(nodes, i, isShift)
+
+				if j-i > 1 && shiftSum(nodes[i:j]) == 0 {
+					i = j
+					continue
+				}
+
+				result = append(result, nodes[i:j]...)
+				i = j
+
+			default:
+				result = append(result, nodes[i])
+				i++
+		}
+	}
+
+	return result
+}
+
+func scanRun(nodes []parser.Node, i int, match func(parser.Node) bool) int {
+	j := i
+
+	for j < len(nodes) && match(nodes[j]) {
+		j++
+	}
+
+	return j
+}
+
+func isIncDec(n parser.Node) bool {
+	switch n.(type) {
+		case *parser.IncNode, *parser.DecNode:
+			return true
+	}
+
+	return false
+}
+
+func isShift(n parser.Node) bool {
+	switch n.(type) {
+		case *parser.NextNode, *parser.PrevNode:
+			return true
+	}
+
+	return false
+}
+
+func incDecSum(nodes []parser.Node) int {
+	sum := 0
+
+	for _, node := range nodes {
+		switch n := node.(type) {
+			case *parser.IncNode:
+				sum += n.Count()
+			case *parser.DecNode:
+				sum -= n.Count()
+		}
+	}
+
+	return sum
+}
+
+func shiftSum(nodes []parser.Node) int {
+	sum := 0
+
+	for _, node := range nodes {
+		switch n := node.(type) {
+			case *parser.NextNode:
+				sum += n.Count()
+			case *parser.PrevNode:
+				sum -= n.Count()
+		}
+	}
+
+	return sum
+}
@@ -0,0 +1,57 @@
+// Package opt rewrites a parsed program into an equivalent one that is
+// cheaper to run, as a pipeline of small, independent passes.
+package opt
+
+import (
+	"../parser"
+)
+
+
+// Pass rewrites a ParseList into an equivalent, hopefully cheaper one.
+type Pass interface{
+	Run(*parser.ParseList) *parser.ParseList
+	Name() string
+}
+
+
+// Default is the pipeline run by Optimize, in the order the passes
+// should be applied: clear loops and copy/multiply loops are recognized
+// before dead code and contraction clean up what's left.
+var Default = []Pass{
+	ClearLoop{},
+	CopyMultiplyLoop{},
+	DeadCodePass{},
+	Contract{},
+}
+
+
+// Optimize runs p through the default pass pipeline.
+func Optimize(p *parser.ParseList) *parser.ParseList {
+	for _, pass := range Default {
+		p = pass.Run(p)
+	}
+
+	return p
+}
+
+
+// transformTree rewrites nodes bottom-up: f is applied to the (already
+// rewritten) children of every LoopNode/FunctionNode before being
+// applied to nodes itself, so passes only have to reason about a single
+// flat list of siblings at a time.
+func transformTree(nodes []parser.Node, f func([]parser.Node) []parser.Node) []parser.Node {
+	rewritten := make([]parser.Node, len(nodes))
+
+	for i, node := range nodes {
+		switch n := node.(type) {
+			case *parser.LoopNode:
+				rewritten[i] = &parser.LoopNode{BaseNode: n.BaseNode, Nodes: transformTree(n.Nodes, f)}
+			case *parser.FunctionNode:
+				rewritten[i] = &parser.FunctionNode{BaseNode: n.BaseNode, Nodes: transformTree(n.Nodes, f)}
+			default:
+				rewritten[i] = node
+		}
+	}
+
+	return f(rewritten)
+}
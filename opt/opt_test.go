@@ -0,0 +1,70 @@
+package opt
+
+import (
+	"testing"
+
+	"../parser"
+)
+
+
+func TestClearLoopRewritesToSetNode(t *testing.T) {
+	p, err := parser.Parse("+++[-]")
+
+	if err != nil {
+		t.Fatalf("Parse failed: %s\n", err)
+	}
+
+	out := ClearLoop{}.Run(p)
+
+	// Nodes[0] is the implicit PreambleNode, Nodes[1] the leading "+++".
+	if _, ok := out.Nodes[2].(*parser.SetNode); !ok {
+		t.Fatalf("Expected [-] to become a SetNode, got %T\n", out.Nodes[2])
+	}
+}
+
+func TestCopyMultiplyLoopRewritesToMulAddNode(t *testing.T) {
+	p, err := parser.Parse("+++[->+++<]")
+
+	if err != nil {
+		t.Fatalf("Parse failed: %s\n", err)
+	}
+
+	out := CopyMultiplyLoop{}.Run(p)
+
+	mulAdd, ok := out.Nodes[2].(*parser.MulAddNode)
+
+	if !ok {
+		t.Fatalf("Expected [->+++<] to become a MulAddNode, got %T\n", out.Nodes[2])
+	}
+
+	if len(mulAdd.Targets) != 1 || mulAdd.Targets[0].Offset != 1 || mulAdd.Targets[0].Factor != 3 {
+		t.Fatalf("Unexpected targets: %#v\n", mulAdd.Targets)
+	}
+}
+
+func TestDeadCodePassDropsCancellingRuns(t *testing.T) {
+	p, err := parser.Parse("+-.")
+
+	if err != nil {
+		t.Fatalf("Parse failed: %s\n", err)
+	}
+
+	out := DeadCodePass{}.Run(p)
+
+	for _, node := range out.Nodes {
+		switch node.(type) {
+			case *parser.IncNode, *parser.DecNode, *parser.NextNode, *parser.PrevNode:
+				t.Fatalf("Expected +- to be fully eliminated, found %T\n", node)
+		}
+	}
+}
+
+func TestOptimizePipelineRuns(t *testing.T) {
+	p, err := parser.Parse("+++[-]")
+
+	if err != nil {
+		t.Fatalf("Parse failed: %s\n", err)
+	}
+
+	Optimize(p)
+}
@@ -0,0 +1,83 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+)
+
+
+// ParseError is a single parse failure: FaultyNode is where the problem
+// was found, OpenNode (when not nil) is the bracket it didn't correctly
+// close.
+type ParseError struct{
+	FaultyNode	Node
+	OpenNode	Node
+	Message		string
+
+	file	*File
+}
+
+func (p *ParseError) Error() string {
+	if p.file == nil {
+		return fmt.Sprintf("Error: %s, Position: %d - %d\n", p.Message, p.FaultyNode.Pos(), p.FaultyNode.End())
+	}
+
+	pos := p.file.Position(p.FaultyNode.Pos())
+	msg := fmt.Sprintf("%s: %s", pos, p.Message)
+
+	if p.OpenNode != nil {
+		msg += fmt.Sprintf(" (opened at %s)", p.file.Position(p.OpenNode.Pos()))
+	}
+
+	return msg + "\n" + p.file.Snippet(p.FaultyNode.Pos())
+}
+
+func parseError(file *File, n Node, open Node, msg string) *ParseError {
+	return &ParseError{
+		FaultyNode:	n,
+		OpenNode:	open,
+		Message:	msg,
+		file:		file,
+	}
+}
+
+
+// ErrorList collects every error found during a single parse, so callers
+// don't have to stop at the first one.
+type ErrorList []*ParseError
+
+// Add appends err, ignoring nil, so callers can record errors
+// unconditionally.
+func (e *ErrorList) Add(err *ParseError) {
+	if err == nil {
+		return
+	}
+
+	*e = append(*e, err)
+}
+
+func (e ErrorList) Error() string {
+	switch len(e) {
+		case 0:
+			return "no errors"
+		case 1:
+			return e[0].Error()
+	}
+
+	parts := make([]string, len(e))
+
+	for i, err := range e {
+		parts[i] = err.Error()
+	}
+
+	return fmt.Sprintf("%d errors:\n%s", len(e), strings.Join(parts, "\n"))
+}
+
+// Err returns e as an error, or nil if e is empty.
+func (e ErrorList) Err() error {
+	if len(e) == 0 {
+		return nil
+	}
+
+	return e
+}
@@ -0,0 +1,43 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+
+func TestParseUnmatchedCloseReportsPosition(t *testing.T) {
+	_, err := Parse("++]")
+
+	if err == nil {
+		t.Fatalf("Expected an error for an unmatched ']'\n")
+	}
+
+	if !strings.Contains(err.Error(), "<input>:1:3") {
+		t.Fatalf("Expected error to point at 1:3, got: %s\n", err.Error())
+	}
+}
+
+func TestParseUnclosedLoopReportsOpener(t *testing.T) {
+	_, err := Parse("+[+")
+
+	if err == nil {
+		t.Fatalf("Expected an error for an unclosed '['\n")
+	}
+
+	if !strings.Contains(err.Error(), "opened at <input>:1:2") {
+		t.Fatalf("Expected error to reference the opening '[' at 1:2, got: %s\n", err.Error())
+	}
+}
+
+func TestParseMismatchedBracketKinds(t *testing.T) {
+	_, err := Parse("[+}")
+
+	if err == nil {
+		t.Fatalf("Expected an error for '[' closed with '}'\n")
+	}
+
+	if !strings.Contains(err.Error(), "Mismatched closing bracket") {
+		t.Fatalf("Expected a mismatched bracket error, got: %s\n", err.Error())
+	}
+}
@@ -0,0 +1,54 @@
+package parser
+
+import (
+	"fmt"
+)
+
+
+// SetNode sets the current cell to a constant value. It typically
+// replaces a loop like "[-]" or "[+]" once an optimizer has proven the
+// loop only ever clears the cell.
+type SetNode struct{
+	BaseNode
+	Value	byte
+}
+
+func (n *SetNode) Code() string {
+	return fmt.Sprintf("registers[currentIndex] = %d\n", n.Value)
+}
+
+
+// MulAddTarget describes one "target += factor * current" effect of a
+// MulAddNode, where target is relative to the cell the loop ran on.
+type MulAddTarget struct{
+	Offset	int
+	Factor	int
+}
+
+// MulAddNode replaces a loop that only ever redistributes the current
+// cell's value onto other cells in fixed multiples of itself, such as
+// "[->+++<]" (Targets: [{Offset:1, Factor:3}]), and then zeroes itself.
+type MulAddNode struct{
+	BaseNode
+	Targets	[]MulAddTarget
+}
+
+func (n *MulAddNode) Code() string {
+	code := ""
+
+	for _, target := range n.Targets {
+		code += fmt.Sprintf(`
+	{
+		targetIndex := (currentIndex + %d) %% REGISTERS
+		if targetIndex < 0 {
+			targetIndex += REGISTERS
+		}
+		registers[targetIndex] += registers[currentIndex] * %d
+	}
+`, target.Offset, byte(target.Factor))
+	}
+
+	code += "registers[currentIndex] = 0\n"
+
+	return code
+}
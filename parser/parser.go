@@ -282,7 +282,7 @@ func (t *TokenList) Append(n Node) {
 
 
 
-func Tokenize(s string) *TokenList {
+func Tokenize(filename, s string) (*TokenList, *File) {
 	t := &TokenList{}
 
 	t.Append(&PreambleNode{ BaseNode{0,0}, false, false })
@@ -318,7 +318,7 @@ func Tokenize(s string) *TokenList {
 
 	t.Append(&PostambleNode{ BaseNode{0,0} })
 
-	return t
+	return t, NewFile(filename, s)
 }
 
 
@@ -331,44 +331,39 @@ func (p *ParseList) Append(n Node) {
 }
 
 
-type ParseError struct {
-	FaultyNode	Node
-	Message		string
-}
-
-
-func (p *ParseError) Error() string {
-	return p.Message
-}
-
-
-func parseError(n Node, msg string) *ParseError {
-	return &ParseError{
-		n,
-		fmt.Sprintf("Error: %s, Position: %d - %d\n", msg, n.Pos(), n.End()),
-	}
-}
-
-
-func ParseTokens(t *TokenList, nesting int) (*ParseList, int, error) {
+// ParseTokens walks t, nesting deep inside "[...]"/"{...}" bodies,
+// tracking open brackets on stack so it can point at the bracket a
+// mismatched or missing close belongs to. Recoverable problems (a close
+// token that doesn't match what's open) are recorded in errs and
+// parsing continues, so a single parse can report more than one error.
+//
+// pre is the top-level PreambleNode; a "," or "." anywhere, including
+// deep inside a "[...]"/"{...}" body, flips its UseIO/UseFmt flags so
+// Generate still knows what imports the program needs.
+func ParseTokens(t *TokenList, nesting int, stack *[]Node, file *File, errs *ErrorList, pre *PreambleNode) (*ParseList, int, error) {
 	p := &ParseList{}
 
 	for i := 0; i < len(t.Nodes); i++ {
 		unknownNode := t.Nodes[i]
 
 		switch unknownNode.(type) {
+			case *PreambleNode:
+				pre = unknownNode.(*PreambleNode)
+				p.Append(unknownNode)
+
 			case *LoopOpenNode:
-				p2, skip, err := ParseTokens(&TokenList{t.Nodes[i+1:], nil}, nesting+1)
+				*stack = append(*stack, unknownNode)
+
+				p2, skip, err := ParseTokens(&TokenList{t.Nodes[i+1:], nil}, nesting+1, stack, file, errs, pre)
 
 				if err != nil {
 					return nil, 0, err
 				}
 
+				*stack = (*stack)[:len(*stack)-1]
+
 				p.Append(&LoopNode{
-					BaseNode{
-						p2.Nodes[0].Pos(),
-						p2.Nodes[len(p2.Nodes)-1].End(),
-					},
+					loopBaseNode(unknownNode, p2.Nodes),
 					p2.Nodes,
 				})
 
@@ -376,24 +371,31 @@ func ParseTokens(t *TokenList, nesting int) (*ParseList, int, error) {
 
 			case *LoopCloseNode:
 				if nesting == 0 {
-					return nil, 0, parseError(unknownNode, "Loop closed while not open")
+					errs.Add(parseError(file, unknownNode, nil, "Loop closed while not open"))
+					continue
+				}
+
+				if _, ok := (*stack)[len(*stack)-1].(*LoopOpenNode); !ok {
+					errs.Add(parseError(file, unknownNode, (*stack)[len(*stack)-1], "Mismatched closing bracket: expected '}' to close, found ']'"))
+					continue
 				}
 
 				// +1 for the skipped LoopCloseNode
 				return p, i+1, nil
 
 			case *FuncOpenNode:
-				p2, skip, err := ParseTokens(&TokenList{t.Nodes[i+1:], nil}, nesting+1)
+				*stack = append(*stack, unknownNode)
+
+				p2, skip, err := ParseTokens(&TokenList{t.Nodes[i+1:], nil}, nesting+1, stack, file, errs, pre)
 
 				if err != nil {
 					return nil, 0, err
 				}
 
+				*stack = (*stack)[:len(*stack)-1]
+
 				p.Append(&FunctionNode{
-					BaseNode{
-						p2.Nodes[0].Pos(),
-						p2.Nodes[len(p2.Nodes)-1].End(),
-					},
+					loopBaseNode(unknownNode, p2.Nodes),
 					p2.Nodes,
 				})
 
@@ -401,17 +403,23 @@ func ParseTokens(t *TokenList, nesting int) (*ParseList, int, error) {
 
 			case *FuncCloseNode:
 				if nesting == 0 {
-					return nil, 0, parseError(unknownNode, "Func closed while not open")
+					errs.Add(parseError(file, unknownNode, nil, "Func closed while not open"))
+					continue
+				}
+
+				if _, ok := (*stack)[len(*stack)-1].(*FuncOpenNode); !ok {
+					errs.Add(parseError(file, unknownNode, (*stack)[len(*stack)-1], "Mismatched closing bracket: expected ']' to close, found '}'"))
+					continue
 				}
 
 				return p, i+1, nil
 
 			case *InputNode:
-				p.Nodes[0].(*PreambleNode).UseIO = true
+				pre.UseIO = true
 				p.Append(unknownNode)
 
 			case *OutputNode:
-				p.Nodes[0].(*PreambleNode).UseFmt = true
+				pre.UseFmt = true
 				p.Append(unknownNode)
 
 			case Encodable:
@@ -419,16 +427,57 @@ func ParseTokens(t *TokenList, nesting int) (*ParseList, int, error) {
 		}
 	}
 
+	if nesting > 0 {
+		opener := (*stack)[len(*stack)-1]
+		pe := parseError(file, eofNode(file), opener, "Reached end of input with an unclosed bracket")
+
+		errs.Add(pe)
+
+		// Unlike a mismatched close, there is no more input to recover
+		// with - bail out all the way instead of letting callers treat
+		// this (incomplete) body as if it were a closed one.
+		return nil, 0, pe
+	}
+
 	return p, 0, nil
 }
 
+// loopBaseNode computes the span a LoopNode/FunctionNode covers, falling
+// back to the opening token's own position for an empty body like "[]".
+func loopBaseNode(opener Node, body []Node) BaseNode {
+	if len(body) == 0 {
+		return BaseNode{opener.Pos(), opener.End()}
+	}
+
+	return BaseNode{body[0].Pos(), body[len(body)-1].End()}
+}
+
+func eofNode(file *File) Node {
+	offset := 0
+
+	if file != nil {
+		offset = len(file.src)
+	}
+
+	return &BaseNode{offset, offset}
+}
 
+
+// Parse tokenizes and parses s, reporting every error it finds (not just
+// the first) via the returned error's underlying ErrorList.
 func Parse(s string) (*ParseList, error) {
-	t := Tokenize(s)
+	t, file := Tokenize("<input>", s)
+
+	errs := &ErrorList{}
+	stack := []Node{}
 
-	p, _, err := ParseTokens(t, 0)
+	p, _, err := ParseTokens(t, 0, &stack, file, errs, nil)
+
+	if err != nil {
+		return nil, errs.Err()
+	}
 
-	return p, err
+	return p, errs.Err()
 }
 
 
@@ -5,30 +5,72 @@ import (
 )
 
 
+// body strips the PreambleNode/PostambleNode sentinels Tokenize always
+// wraps its output in, leaving only the nodes for s itself.
+func body(l *TokenList) []Node {
+	return l.Nodes[1 : len(l.Nodes)-1]
+}
+
 func TestSummary(t *testing.T) {
 
-	l := Tokenize("+++")
+	l, _ := Tokenize("test.bf", "+++")
 
-	if len(l.Nodes) > 1 {
-		t.Fatalf("Not summarized: len(l.Nodes) > 1 (%d)\n", len(l.Nodes))
+	if len(body(l)) > 1 {
+		t.Fatalf("Not summarized: len(body(l)) > 1 (%d)\n", len(body(l)))
 	}
 
-	count := l.Nodes[0].(Summarizable).Count()
+	count := body(l)[0].(Summarizable).Count()
 
 	if count != 3 {
 		t.Fatalf("Count mismatched: Count != 3 but %d\n", count)
 	}
 
-	l = Tokenize("[[[")
+	l, _ = Tokenize("test.bf", "[[[")
+
+	if len(body(l)) != 3 {
+		t.Fatalf("Wrongly summarized (not allowed): len(body(d)) = %d\n", len(body(l)))
+	}
+
+	l, _ = Tokenize("test.bf", "+-+")
 
-	if len(l.Nodes) != 3 {
-		t.Fatalf("Wrongly summarized (not allowed): len(d.Nodes) = %d\n", len(l.Nodes))
+	if len(body(l)) != 3 {
+		t.Fatalf("Mixed types summarized: len(body(d)) = %d\n", len(body(l)))
 	}
 
-	l = Tokenize("+-+")
+}
 
-	if len(l.Nodes) != 3 {
-		t.Fatalf("Mixed types summarized: len(d.Nodes) = %d\n", len(l.Nodes))
+// TestParseIOInsideNesting covers the cat program and variants where
+// "," / "." only ever occur inside a "[...]"/"{...}" body: ParseTokens
+// recurses into those bodies with a fresh ParseList that has no
+// PreambleNode of its own, so the UseIO/UseFmt flags must thread
+// through rather than being read off the nested list.
+func TestParseIOInsideNesting(t *testing.T) {
+	cases := []struct{
+		prog			string
+		useIO, useFmt	bool
+	}{
+		{",[.,]", true, true},
+		{"[.]", false, true},
+		{"+[-.]", false, true},
+		{"{.}", false, true},
+		{"{,}", true, false},
 	}
 
+	for _, c := range cases {
+		p, err := Parse(c.prog)
+
+		if err != nil {
+			t.Fatalf("Parse(%q) failed: %s\n", c.prog, err)
+		}
+
+		pre, ok := p.Nodes[0].(*PreambleNode)
+
+		if !ok {
+			t.Fatalf("Parse(%q): expected a PreambleNode at Nodes[0], got %T\n", c.prog, p.Nodes[0])
+		}
+
+		if pre.UseIO != c.useIO || pre.UseFmt != c.useFmt {
+			t.Fatalf("Parse(%q): UseIO/UseFmt = %v/%v, want %v/%v\n", c.prog, pre.UseIO, pre.UseFmt, c.useIO, c.useFmt)
+		}
+	}
 }
@@ -0,0 +1,111 @@
+package parser
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+
+// Position is a human-readable location in a source file, in the spirit
+// of go/token.Position.
+type Position struct{
+	Filename	string
+	Offset		int
+	Line		int
+	Column		int
+}
+
+func (p Position) String() string {
+	if p.Filename == "" {
+		return fmt.Sprintf("%d:%d", p.Line, p.Column)
+	}
+
+	return fmt.Sprintf("%s:%d:%d", p.Filename, p.Line, p.Column)
+}
+
+
+// File maps byte offsets into a single source file to line/column
+// Positions. Line boundaries are computed the first time they're
+// needed instead of while tokenizing.
+type File struct{
+	name		string
+	src			string
+	lineStarts	[]int
+}
+
+func NewFile(name, src string) *File {
+	return &File{name: name, src: src}
+}
+
+func (f *File) Name() string {
+	return f.name
+}
+
+func (f *File) lines() []int {
+	if f.lineStarts == nil {
+		starts := []int{0}
+
+		for i := 0; i < len(f.src); i++ {
+			if f.src[i] == '\n' {
+				starts = append(starts, i+1)
+			}
+		}
+
+		f.lineStarts = starts
+	}
+
+	return f.lineStarts
+}
+
+// Position translates a byte offset into f's source to a line/column.
+func (f *File) Position(offset int) Position {
+	starts := f.lines()
+
+	line := sort.Search(len(starts), func(i int) bool { return starts[i] > offset }) - 1
+
+	if line < 0 {
+		line = 0
+	}
+
+	return Position{f.name, offset, line + 1, offset - starts[line] + 1}
+}
+
+// Snippet renders the source line containing offset together with a
+// caret underneath pointing at the exact column.
+func (f *File) Snippet(offset int) string {
+	starts := f.lines()
+	pos := f.Position(offset)
+
+	lineStart := starts[pos.Line-1]
+	lineEnd := len(f.src)
+
+	if pos.Line < len(starts) {
+		lineEnd = starts[pos.Line] - 1
+	}
+
+	line := f.src[lineStart:lineEnd]
+	caret := strings.Repeat(" ", pos.Column-1) + "^"
+
+	return line + "\n" + caret
+}
+
+
+// FileSet groups the source files known to a single parse.
+type FileSet struct{
+	files	map[string]*File
+}
+
+func NewFileSet() *FileSet {
+	return &FileSet{files: map[string]*File{}}
+}
+
+func (fs *FileSet) AddFile(name, src string) *File {
+	f := NewFile(name, src)
+	fs.files[name] = f
+	return f
+}
+
+func (fs *FileSet) File(name string) *File {
+	return fs.files[name]
+}
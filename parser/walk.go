@@ -0,0 +1,78 @@
+package parser
+
+// Pos and End make *ParseList satisfy Node so Walk/Inspect can be
+// started from the top-level result of Parse, just like from any
+// LoopNode or FunctionNode further down the tree.
+
+func (p *ParseList) Pos() int {
+	if len(p.Nodes) == 0 {
+		return 0
+	}
+
+	return p.Nodes[0].Pos()
+}
+
+func (p *ParseList) End() int {
+	if len(p.Nodes) == 0 {
+		return 0
+	}
+
+	return p.Nodes[len(p.Nodes)-1].End()
+}
+
+
+// Visitor visits nodes of a parsed program. If Visit returns nil, the
+// children of node are not visited.
+//
+// Modeled on go/ast's Visitor/Walk.
+type Visitor interface{
+	Visit(node Node) (w Visitor)
+}
+
+
+// Walk traverses node in depth-first order: it calls v.Visit(node); if
+// the visitor w returned by v.Visit(node) is not nil, Walk visits each
+// of node's children with w, then calls w.Visit(nil).
+func Walk(v Visitor, node Node) {
+	if v = v.Visit(node); v == nil {
+		return
+	}
+
+	switch n := node.(type) {
+		case *ParseList:
+			for _, child := range n.Nodes {
+				Walk(v, child)
+			}
+
+		case *LoopNode:
+			for _, child := range n.Nodes {
+				Walk(v, child)
+			}
+
+		case *FunctionNode:
+			for _, child := range n.Nodes {
+				Walk(v, child)
+			}
+	}
+
+	v.Visit(nil)
+}
+
+
+type inspector func(Node) bool
+
+func (f inspector) Visit(node Node) Visitor {
+	if f(node) {
+		return f
+	}
+
+	return nil
+}
+
+
+// Inspect traverses node in depth-first order: it calls f(node); if f
+// returns true, Inspect invokes f recursively for each of node's
+// children, followed by a call of f(nil).
+func Inspect(node Node, f func(Node) bool) {
+	Walk(inspector(f), node)
+}
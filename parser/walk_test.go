@@ -0,0 +1,28 @@
+package parser
+
+import (
+	"testing"
+)
+
+
+func TestInspectVisitsNestedLoops(t *testing.T) {
+	p, err := Parse("+[-[+]]")
+
+	if err != nil {
+		t.Fatalf("Parse failed: %s\n", err)
+	}
+
+	loops := 0
+
+	Inspect(p, func(n Node) bool {
+		if _, ok := n.(*LoopNode); ok {
+			loops++
+		}
+
+		return true
+	})
+
+	if loops != 2 {
+		t.Fatalf("Expected 2 loops, found %d\n", loops)
+	}
+}
@@ -0,0 +1,210 @@
+package vm
+
+import (
+	"../parser"
+	"fmt"
+)
+
+
+// compiler turns a *parser.ParseList into a flat Program in a single
+// pass, patching loop jump targets once the loop body has been emitted.
+type compiler struct{
+	code		[]Instr
+	loopStack	[]int
+	useIO		bool
+	useFmt		bool
+}
+
+func (c *compiler) emit(op Op, a, b int) int {
+	c.code = append(c.code, Instr{op, a, b})
+	return len(c.code) - 1
+}
+
+func (c *compiler) compileAll(nodes []parser.Node) error {
+	for _, node := range nodes {
+		if err := c.compileNode(node); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *compiler) compileNode(node parser.Node) error {
+	switch n := node.(type) {
+		case *parser.PreambleNode:
+			c.useIO = n.UseIO
+			c.useFmt = n.UseFmt
+
+		case *parser.PostambleNode:
+			// Nothing to emit.
+
+		case *parser.SetNode:
+			if n.Value == 0 {
+				c.emit(OpZero, 0, 0)
+			} else {
+				c.emit(OpZero, 0, 0)
+				c.emit(OpAdd, int(n.Value), 0)
+			}
+
+		case *parser.MulAddNode:
+			for _, target := range n.Targets {
+				c.emit(OpAddTo, target.Offset, target.Factor)
+			}
+			c.emit(OpZero, 0, 0)
+
+		case *parser.IncNode:
+			c.emit(OpAdd, n.Count(), 0)
+
+		case *parser.DecNode:
+			c.emit(OpSub, n.Count(), 0)
+
+		case *parser.PrevNode:
+			c.emit(OpShiftL, n.Count(), 0)
+
+		case *parser.NextNode:
+			c.emit(OpShiftR, n.Count(), 0)
+
+		case *parser.OutputNode:
+			c.emit(OpOut, 0, 0)
+
+		case *parser.InputNode:
+			c.emit(OpIn, 0, 0)
+
+		case *parser.LoopNode:
+			return c.compileLoop(n)
+
+		case *parser.FunctionNode:
+			defPC := c.emit(OpDefFunc, 0, 0)
+
+			if err := c.compileAll(n.Nodes); err != nil {
+				return err
+			}
+
+			c.emit(OpFuncEnd, 0, 0)
+
+			c.code[defPC].A = len(c.code)
+
+		case *parser.FuncExecNode:
+			c.emit(OpCallFunc, 0, 0)
+
+		default:
+			return fmt.Errorf("vm: unsupported node type %T", node)
+	}
+
+	return nil
+}
+
+// compileLoop recognizes a couple of very common loop idioms ("[-]" and
+// single-target copy/multiply loops like "[->+++<]") and lowers them to
+// fused instructions (OpZero, or OpMoveTo/OpAddTo followed by OpZero)
+// instead of a full jump-guarded loop.
+func (c *compiler) compileLoop(n *parser.LoopNode) error {
+	if isClearLoop(n.Nodes) {
+		c.emit(OpZero, 0, 0)
+		return nil
+	}
+
+	if target, factor, ok := asAddToLoop(n.Nodes); ok {
+		if factor == 1 {
+			c.emit(OpMoveTo, target, 0)
+		} else {
+			c.emit(OpAddTo, target, factor)
+		}
+		c.emit(OpZero, 0, 0)
+		return nil
+	}
+
+	jzPC := c.emit(OpJZ, 0, 0)
+
+	if err := c.compileAll(n.Nodes); err != nil {
+		return err
+	}
+
+	c.emit(OpJNZ, jzPC+1, 0)
+
+	c.code[jzPC].A = len(c.code)
+
+	return nil
+}
+
+// isClearLoop reports whether nodes is exactly "[-]" or "[+]".
+func isClearLoop(nodes []parser.Node) bool {
+	if len(nodes) != 1 {
+		return false
+	}
+
+	switch n := nodes[0].(type) {
+		case *parser.DecNode:
+			return n.Count() == 1
+		case *parser.IncNode:
+			return n.Count() == 1
+	}
+
+	return false
+}
+
+// asAddToLoop recognizes loops of the form "[- (>|<)+ (<|>) ]" where the
+// current cell is decremented by one per iteration and the only other
+// effect is adding a constant amount to exactly one other cell, with the
+// data pointer returned to its starting position. It returns the target
+// cell's offset and the per-iteration factor added to it.
+func asAddToLoop(nodes []parser.Node) (target, factor int, ok bool) {
+	if len(nodes) == 0 {
+		return 0, 0, false
+	}
+
+	dec, ok := nodes[0].(*parser.DecNode)
+
+	if !ok || dec.Count() != 1 {
+		return 0, 0, false
+	}
+
+	offset := 0
+	deltas := map[int]int{}
+
+	for _, node := range nodes[1:] {
+		switch n := node.(type) {
+			case *parser.NextNode:
+				offset += n.Count()
+			case *parser.PrevNode:
+				offset -= n.Count()
+			case *parser.IncNode:
+				deltas[offset] += n.Count()
+			case *parser.DecNode:
+				deltas[offset] -= n.Count()
+			default:
+				return 0, 0, false
+		}
+	}
+
+	if offset != 0 || len(deltas) != 1 {
+		return 0, 0, false
+	}
+
+	for off, delta := range deltas {
+		if off == 0 || delta <= 0 {
+			return 0, 0, false
+		}
+
+		return off, delta, true
+	}
+
+	return 0, 0, false
+}
+
+
+// Compile lowers a parsed program to bytecode.
+func Compile(p *parser.ParseList) (*Program, error) {
+	c := &compiler{}
+
+	if err := c.compileAll(p.Nodes); err != nil {
+		return nil, err
+	}
+
+	return &Program{
+		Code:	c.code,
+		UseIO:	c.useIO,
+		UseFmt:	c.useFmt,
+	}, nil
+}
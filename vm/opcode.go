@@ -0,0 +1,36 @@
+package vm
+
+// Op identifies a single bytecode instruction.
+type Op int
+
+const (
+	OpAdd		Op = iota	// A: amount to add to the current cell
+	OpSub				// A: amount to subtract from the current cell
+	OpShiftL			// A: number of cells to shift left (with wraparound)
+	OpShiftR			// A: number of cells to shift right (with wraparound)
+	OpOut				// write the current cell to Out
+	OpIn				// read one byte into the current cell from In
+	OpJZ				// A: pc to jump to if the current cell is zero
+	OpJNZ				// A: pc to jump to if the current cell is non-zero
+	OpDefFunc			// A: pc right after the function body
+	OpFuncEnd			// return from a function body (see OpDefFunc)
+	OpCallFunc			// call the function bound to the current cell, if any
+	OpZero				// set the current cell to zero ("[-]"/"[+]")
+	OpMoveTo			// A: relative offset; target += current (current is left untouched, pair with OpZero)
+	OpAddTo				// A: relative offset, B: factor; target += factor * current (pair with OpZero)
+)
+
+
+// Instr is a single bytecode instruction together with its operands.
+type Instr struct{
+	Op		Op
+	A, B	int
+}
+
+
+// Program is a compiled, directly runnable bytecode program.
+type Program struct{
+	Code		[]Instr
+	UseIO		bool
+	UseFmt		bool
+}
@@ -0,0 +1,141 @@
+package vm
+
+import (
+	"fmt"
+	"io"
+)
+
+
+const REGISTERS = 100
+
+
+// wrap returns dp+offset as a valid tape index, wrapping in either
+// direction the way the "<"/">" handling in the rest of the VM does.
+func wrap(dp, offset int) int {
+	idx := (dp + offset) % REGISTERS
+
+	if idx < 0 {
+		idx += REGISTERS
+	}
+
+	return idx
+}
+
+
+// Run executes the compiled program, reading OpIn bytes from in and
+// writing OpOut bytes to out.
+func (p *Program) Run(in io.Reader, out io.Writer) error {
+	tape := make([]byte, REGISTERS)
+	dp := 0
+
+	funcs := make([]int, REGISTERS)
+	for i := range funcs {
+		funcs[i] = -1
+	}
+
+	callStack := []int{}
+
+	code := p.Code
+
+	for pc := 0; pc < len(code); {
+		instr := code[pc]
+
+		switch instr.Op {
+			case OpAdd:
+				tape[dp] += byte(instr.A)
+				pc++
+
+			case OpSub:
+				tape[dp] -= byte(instr.A)
+				pc++
+
+			case OpShiftL:
+				for i := 0; i < instr.A; i++ {
+					if dp == 0 {
+						dp = REGISTERS - 1
+					} else {
+						dp--
+					}
+				}
+				pc++
+
+			case OpShiftR:
+				dp = (dp + instr.A) % REGISTERS
+				pc++
+
+			case OpOut:
+				fmt.Fprint(out, string(tape[dp]))
+				pc++
+
+			case OpIn:
+				var b [1]byte
+
+				_, err := in.Read(b[:])
+
+				if err != nil {
+					if err == io.EOF {
+						tape[dp] = 0
+					} else {
+						return err
+					}
+				} else {
+					tape[dp] = b[0]
+				}
+
+				pc++
+
+			case OpJZ:
+				if tape[dp] == 0 {
+					pc = instr.A
+				} else {
+					pc++
+				}
+
+			case OpJNZ:
+				if tape[dp] != 0 {
+					pc = instr.A
+				} else {
+					pc++
+				}
+
+			case OpDefFunc:
+				funcs[dp] = pc + 1
+				pc = instr.A
+
+			case OpFuncEnd:
+				if len(callStack) == 0 {
+					pc++
+				} else {
+					pc = callStack[len(callStack)-1]
+					callStack = callStack[:len(callStack)-1]
+				}
+
+			case OpCallFunc:
+				if funcs[dp] >= 0 {
+					callStack = append(callStack, pc+1)
+					pc = funcs[dp]
+				} else {
+					pc++
+				}
+
+			case OpZero:
+				tape[dp] = 0
+				pc++
+
+			case OpMoveTo:
+				target := wrap(dp, instr.A)
+				tape[target] += tape[dp]
+				pc++
+
+			case OpAddTo:
+				target := wrap(dp, instr.A)
+				tape[target] += tape[dp] * byte(instr.B)
+				pc++
+
+			default:
+				return fmt.Errorf("vm: unknown opcode %d at pc %d", instr.Op, pc)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,110 @@
+package vm
+
+import (
+	"../interp"
+	"../parser"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+
+const helloWorld = `++++++++[>++++[>++>+++>+++>+<<<<-]>+>+>->>+[<]<-]>>.>---.+++++++..+++.>>.<-.<.+++.------.--------.>>+.>++.`
+
+// busyLoop does no I/O, just nested nested counting, so it isolates
+// dispatch/loop overhead from the cost of Run's writes - the kind of
+// tight, loop-heavy work Mandelbrot/hanoi programs spend most of their
+// time in.
+var busyLoop = strings.Repeat("+", 40) + "[>" + strings.Repeat("+", 40) + "[>+<-]<-]"
+
+
+func mustCompile(t *testing.T, src string) *Program {
+	p, err := parser.Parse(src)
+
+	if err != nil {
+		t.Fatalf("Parse failed: %s\n", err)
+	}
+
+	prog, err := Compile(p)
+
+	if err != nil {
+		t.Fatalf("Compile failed: %s\n", err)
+	}
+
+	return prog
+}
+
+func TestRunHelloWorld(t *testing.T) {
+	prog := mustCompile(t, helloWorld)
+	out := &bytes.Buffer{}
+
+	if err := prog.Run(nil, out); err != nil {
+		t.Fatalf("Run failed: %s\n", err)
+	}
+
+	if out.String() != "Hello World!\n" {
+		t.Fatalf("Expected %q, got %q\n", "Hello World!\n", out.String())
+	}
+}
+
+func TestRunClearLoopFusesToZero(t *testing.T) {
+	prog := mustCompile(t, "+++++[-]")
+
+	if len(prog.Code) != 2 || prog.Code[1].Op != OpZero {
+		t.Fatalf("Expected [-] to fuse to a single OpZero, got %#v\n", prog.Code)
+	}
+}
+
+func TestRunAddToLoopFusesToMoveTo(t *testing.T) {
+	prog := mustCompile(t, "+++[->+<]")
+
+	if len(prog.Code) != 3 || prog.Code[1].Op != OpMoveTo || prog.Code[2].Op != OpZero {
+		t.Fatalf("Expected [->+<] to fuse to OpMoveTo+OpZero, got %#v\n", prog.Code)
+	}
+}
+
+
+func BenchmarkRunHelloWorld(b *testing.B) {
+	prog := mustCompileForBench(b, helloWorld)
+
+	for n := 0; n < b.N; n++ {
+		prog.Run(nil, &bytes.Buffer{})
+	}
+}
+
+// BenchmarkRunBusyLoopVM and BenchmarkRunBusyLoopInterp run the same
+// loop-heavy program through the vm and the tree-walking interp
+// respectively, so `go test -bench . -benchmem ./vm` shows the speedup
+// the vm buys over re-walking the AST on every iteration.
+func BenchmarkRunBusyLoopVM(b *testing.B) {
+	prog := mustCompileForBench(b, busyLoop)
+
+	for n := 0; n < b.N; n++ {
+		prog.Run(nil, io.Discard)
+	}
+}
+
+func BenchmarkRunBusyLoopInterp(b *testing.B) {
+	for n := 0; n < b.N; n++ {
+		if err := interp.RunString(busyLoop, nil, io.Discard); err != nil {
+			b.Fatalf("RunString failed: %s\n", err)
+		}
+	}
+}
+
+func mustCompileForBench(b *testing.B, src string) *Program {
+	p, err := parser.Parse(src)
+
+	if err != nil {
+		b.Fatalf("Parse failed: %s\n", err)
+	}
+
+	prog, err := Compile(p)
+
+	if err != nil {
+		b.Fatalf("Compile failed: %s\n", err)
+	}
+
+	return prog
+}